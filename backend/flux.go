@@ -0,0 +1,169 @@
+// Copyright 2021 Shiwen Cheng. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package backend
+
+import (
+	"strings"
+
+	"github.com/chengshiwen/influx-proxy/util"
+)
+
+var SupportFluxWriteFuncs = util.NewSet(
+	"to",
+	"experimental.to",
+)
+
+// ScanFluxTokens splits a Flux query into pipe stages and function calls.
+// Unlike ScanTokens, a token may contain spaces inside a balanced pair of
+// parentheses, so parens are tracked across the whole token rather than
+// only when a token begins with one.
+func ScanFluxTokens(q string, n int) (tokens []string) {
+	data := []byte(strings.TrimSpace(q))
+	pos := 0
+	for pos < len(data) {
+		for pos < len(data) && isFluxSpace(data[pos]) {
+			pos++
+		}
+		if pos >= len(data) {
+			break
+		}
+		if data[pos] == '|' && pos+1 < len(data) && data[pos+1] == '>' {
+			tokens = append(tokens, "|>")
+			pos += 2
+		} else {
+			start := pos
+			bracket := 0
+			for pos < len(data) {
+				switch data[pos] {
+				case '"', '\'':
+					end, _, err := FindEndWithQuote(data, pos, data[pos])
+					if err != nil {
+						pos = len(data)
+					} else {
+						pos = end
+					}
+					continue
+				case '(':
+					bracket++
+				case ')':
+					bracket--
+				}
+				if bracket == 0 && (isFluxSpace(data[pos]) || (data[pos] == '|' && pos+1 < len(data) && data[pos+1] == '>')) {
+					break
+				}
+				pos++
+			}
+			tokens = append(tokens, string(data[start:pos]))
+		}
+		if n > 0 && len(tokens) == n {
+			return
+		}
+	}
+	return
+}
+
+func isFluxSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+func GetBucketFromFlux(q string) (m string, err error) {
+	return GetBucketFromFluxTokens(ScanFluxTokens(q, 0))
+}
+
+func GetBucketFromFluxTokens(tokens []string) (m string, err error) {
+	for i := 0; i < len(tokens); i++ {
+		name, ok := fluxFuncName(tokens[i])
+		if !ok || strings.ToLower(name) != "from" {
+			continue
+		}
+		m = getFluxArg(tokens[i], "bucket")
+		if m != "" {
+			return
+		}
+	}
+	return "", ErrIllegalQL
+}
+
+func GetMeasurementFromFlux(q string) (m string, err error) {
+	return GetMeasurementFromFluxTokens(ScanFluxTokens(q, 0))
+}
+
+func GetMeasurementFromFluxTokens(tokens []string) (m string, err error) {
+	for i := 0; i < len(tokens); i++ {
+		name, ok := fluxFuncName(tokens[i])
+		if !ok || strings.ToLower(name) != "filter" {
+			continue
+		}
+		m = getFluxMeasurementPredicate(tokens[i])
+		if m != "" {
+			return
+		}
+	}
+	return "", ErrIllegalQL
+}
+
+func fluxFuncName(token string) (name string, ok bool) {
+	paren := strings.IndexByte(token, '(')
+	if paren <= 0 || token[len(token)-1] != ')' {
+		return "", false
+	}
+	return token[:paren], true
+}
+
+func getFluxArg(call string, name string) (v string) {
+	paren := strings.IndexByte(call, '(')
+	args := strings.TrimSuffix(call[paren+1:], ")")
+	for _, part := range strings.Split(args, ",") {
+		part = strings.TrimSpace(part)
+		if !strings.HasPrefix(part, name+":") {
+			continue
+		}
+		v = strings.TrimSpace(strings.TrimPrefix(part, name+":"))
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') {
+			v = v[1 : len(v)-1]
+		}
+		return
+	}
+	return ""
+}
+
+func getFluxMeasurementPredicate(call string) (m string) {
+	idx := strings.Index(call, "r._measurement")
+	if idx == -1 {
+		return ""
+	}
+	rest := strings.TrimSpace(call[idx+len("r._measurement"):])
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, "=="))
+	if rest == "" || (rest[0] != '"' && rest[0] != '\'') {
+		return ""
+	}
+	_, unquoted, err := FindEndWithQuote([]byte(rest), 0, rest[0])
+	if err != nil || len(unquoted) < 2 {
+		return ""
+	}
+	return string(unquoted[1 : len(unquoted)-1])
+}
+
+// CheckFluxQuery rejects write functions such as to() or experimental.to(),
+// and queries without a from() call; every other function on the read path
+// is allowed, since Flux's stdlib of
+// filter/transform functions is too large to enumerate as an allow list.
+func CheckFluxQuery(q string) (tokens []string, check bool) {
+	tokens = ScanFluxTokens(q, 0)
+	hasFrom := false
+	for _, token := range tokens {
+		name, ok := fluxFuncName(token)
+		if !ok {
+			continue
+		}
+		if SupportFluxWriteFuncs[strings.ToLower(name)] {
+			return tokens, false
+		}
+		if strings.ToLower(name) == "from" {
+			hasFrom = true
+		}
+	}
+	return tokens, hasFrom
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 Shiwen Cheng. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package backend
+
+import "testing"
+
+func TestScanFluxTokens(t *testing.T) {
+	tests := []struct {
+		q    string
+		want []string
+	}{
+		{
+			`from(bucket: "telegraf") |> range(start: -1h)`,
+			[]string{`from(bucket: "telegraf")`, "|>", "range(start: -1h)"},
+		},
+		{
+			`from(bucket: "telegraf") |> filter(fn: (r) => r._measurement == "cpu" and r._field == "usage")`,
+			[]string{`from(bucket: "telegraf")`, "|>", `filter(fn: (r) => r._measurement == "cpu" and r._field == "usage")`},
+		},
+	}
+	for _, tt := range tests {
+		got := ScanFluxTokens(tt.q, 0)
+		if len(got) != len(tt.want) {
+			t.Fatalf("ScanFluxTokens(%q) = %v, want %v", tt.q, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("ScanFluxTokens(%q)[%d] = %q, want %q", tt.q, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestGetBucketFromFlux(t *testing.T) {
+	m, err := GetBucketFromFlux(`from(bucket: "telegraf") |> range(start: -1h)`)
+	if err != nil || m != "telegraf" {
+		t.Fatalf("GetBucketFromFlux() = (%q, %v), want (%q, nil)", m, err, "telegraf")
+	}
+
+	if _, err := GetBucketFromFlux(`range(start: -1h)`); err == nil {
+		t.Error("GetBucketFromFlux() with no from() call: want error, got nil")
+	}
+}
+
+func TestGetMeasurementFromFlux(t *testing.T) {
+	tests := []struct {
+		q    string
+		want string
+	}{
+		{`from(bucket: "telegraf") |> filter(fn: (r) => r._measurement == "cpu")`, "cpu"},
+		{`from(bucket: "telegraf") |> filter(fn: (r) => r._measurement == "cpu" and r._field == "usage")`, "cpu"},
+		{`from(bucket: "telegraf") |> filter(fn: (r) => r._measurement == 'cpu')`, "cpu"},
+	}
+	for _, tt := range tests {
+		m, err := GetMeasurementFromFlux(tt.q)
+		if err != nil || m != tt.want {
+			t.Errorf("GetMeasurementFromFlux(%q) = (%q, %v), want (%q, nil)", tt.q, m, err, tt.want)
+		}
+	}
+
+	if _, err := GetMeasurementFromFlux(`from(bucket: "telegraf")`); err == nil {
+		t.Error("GetMeasurementFromFlux() with no filter() call: want error, got nil")
+	}
+}
+
+func TestCheckFluxQuery(t *testing.T) {
+	tests := []struct {
+		q     string
+		check bool
+	}{
+		{`from(bucket: "telegraf") |> range(start: -1h) |> mean()`, true},
+		{`from(bucket: "telegraf") |> range(start: -1h) |> sort() |> limit(n: 10)`, true},
+		{`from(bucket: "telegraf") |> to(bucket: "other")`, false},
+		{`from(bucket: "telegraf") |> experimental.to(bucket: "other")`, false},
+		{`range(start: -1h)`, false},
+	}
+	for _, tt := range tests {
+		_, check := CheckFluxQuery(tt.q)
+		if check != tt.check {
+			t.Errorf("CheckFluxQuery(%q) = %v, want %v", tt.q, check, tt.check)
+		}
+	}
+}
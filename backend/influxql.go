@@ -341,39 +341,55 @@ func FindLastIndexWithIdent(m string) (i int) {
 	return strings.LastIndexByte(m, '.')
 }
 
-func CheckQuery(q string) (tokens []string, check bool, from bool) {
+// CheckQuery reports whether q is supported and whether it needs
+// measurement-based routing (from). known expands a regex measurement
+// literal into the matching names; it may be nil otherwise.
+func CheckQuery(q string, known []string) (tokens []string, check bool, from bool, measurements []string, err error) {
 	tokens = ScanTokens(q, 0)
 	stmt := strings.ToLower(tokens[0])
 	if stmt == "select" {
 		for i := 2; i < len(tokens); i++ {
 			stmt := strings.ToLower(tokens[i])
 			if stmt == "into" {
-				return tokens, false, false
+				_, _, _, ierr := CheckIntoQuery(q)
+				return tokens, ierr == nil, true, nil, ierr
 			}
 			if stmt == "from" {
-				return tokens, true, true
+				return tokens, true, true, nil, nil
 			}
 		}
-		return tokens, false, false
+		return tokens, false, false, nil, nil
 	}
 	if stmt == "show" {
 		for i := 2; i < len(tokens); i++ {
 			stmt := strings.ToLower(tokens[i])
 			if stmt == "from" {
 				check = SupportCmds[GetHeadStmtFromTokens(tokens, i)] || SupportCmds[GetHeadStmtFromTokens(tokens, i-2)]
-				return tokens, check, true
+				measurements, err = expandIfRegexMeasurement(tokens, known)
+				return tokens, check, true, measurements, err
 			}
 		}
 	}
 	stmt2 := GetHeadStmtFromTokens(tokens, 2)
 	if SupportCmds[stmt2] {
-		return tokens, true, stmt2 == "delete from" || stmt2 == "drop measurement"
+		from = stmt2 == "delete from" || stmt2 == "drop measurement"
+		measurements, err = expandIfRegexMeasurement(tokens, known)
+		return tokens, true, from, measurements, err
 	}
 	stmt3 := GetHeadStmtFromTokens(tokens, 3)
 	if SupportCmds[stmt3] {
-		return tokens, true, false
+		return tokens, true, false, nil, nil
 	}
-	return tokens, false, false
+	return tokens, false, false, nil, nil
+}
+
+// expandIfRegexMeasurement is a no-op for a plain measurement name.
+func expandIfRegexMeasurement(tokens []string, known []string) (measurements []string, err error) {
+	m, merr := GetMeasurementFromTokens(tokens)
+	if merr != nil || !IsRegexMeasurement(m) {
+		return nil, nil
+	}
+	return ExpandRegexMeasurement(tokens, known)
 }
 
 func CheckShowDatabasesFromTokens(tokens []string) (check bool) {
@@ -0,0 +1,66 @@
+// Copyright 2021 Shiwen Cheng. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package backend
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrIntoCrossShard = errors.New("select into: source and destination measurements route to different shards")
+
+func GetIntoTargetFromTokens(tokens []string) (db, rp, measurement string, err error) {
+	idx := -1
+	for i := 0; i < len(tokens); i++ {
+		if strings.ToLower(tokens[i]) == "into" {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 || idx+1 >= len(tokens) {
+		return "", "", "", ErrIllegalQL
+	}
+
+	// rest starts at the qualified target name itself, not at a "FROM
+	// <target>" clause, so parse it directly instead of through the
+	// keyword-seeking GetDatabaseFromTokens & co.
+	rest := tokens[idx+1:]
+	measurement = getMeasurement(rest, "from")
+	if measurement == "" {
+		return "", "", "", ErrIllegalQL
+	}
+	db = getDatabase(rest, "from")
+	rp = getRetentionPolicy(rest, "from")
+	return db, rp, measurement, nil
+}
+
+func CheckIntoQuery(q string) (tokens []string, srcMeasurement string, dstMeasurement string, err error) {
+	tokens = ScanTokens(q, 0)
+	if len(tokens) == 0 || strings.ToLower(tokens[0]) != "select" {
+		return tokens, "", "", ErrIllegalQL
+	}
+
+	_, _, dstMeasurement, err = GetIntoTargetFromTokens(tokens)
+	if err != nil {
+		return tokens, "", "", err
+	}
+
+	for i := 2; i < len(tokens); i++ {
+		if strings.ToLower(tokens[i]) == "from" {
+			srcMeasurement, err = GetMeasurementFromTokens(tokens[i:])
+			if err != nil {
+				return tokens, "", "", ErrIllegalQL
+			}
+			break
+		}
+	}
+	if srcMeasurement == "" {
+		return tokens, "", "", ErrIllegalQL
+	}
+	if !SameShard(srcMeasurement, dstMeasurement) {
+		return tokens, srcMeasurement, dstMeasurement, ErrIntoCrossShard
+	}
+	return tokens, srcMeasurement, dstMeasurement, nil
+}
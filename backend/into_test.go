@@ -0,0 +1,70 @@
+// Copyright 2021 Shiwen Cheng. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package backend
+
+import "testing"
+
+func TestGetIntoTargetFromTokens(t *testing.T) {
+	tests := []struct {
+		q      string
+		db     string
+		rp     string
+		m      string
+		hasErr bool
+	}{
+		{`select mean(v) into "mydb"."myrp"."dest" from "src"`, "mydb", "myrp", "dest", false},
+		{`select mean(v) into dest from src`, "", "", "dest", false},
+		{`select mean(v) from src`, "", "", "", true},
+	}
+	for _, tt := range tests {
+		db, rp, m, err := GetIntoTargetFromTokens(ScanTokens(tt.q, 0))
+		if (err != nil) != tt.hasErr {
+			t.Errorf("GetIntoTargetFromTokens(%q) err = %v, want hasErr %v", tt.q, err, tt.hasErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if db != tt.db || rp != tt.rp || m != tt.m {
+			t.Errorf("GetIntoTargetFromTokens(%q) = (%q, %q, %q), want (%q, %q, %q)", tt.q, db, rp, m, tt.db, tt.rp, tt.m)
+		}
+	}
+}
+
+func TestCheckIntoQuerySameShard(t *testing.T) {
+	old := ShardCount
+	defer func() { ShardCount = old }()
+	ShardCount = 1
+
+	_, src, dst, err := CheckIntoQuery(`select mean(v) into cq_cpu from cpu`)
+	if err != nil {
+		t.Fatalf("CheckIntoQuery() with ShardCount=1 should always accept, got err %v", err)
+	}
+	if src != "cpu" || dst != "cq_cpu" {
+		t.Errorf("CheckIntoQuery() src/dst = %q/%q, want cpu/cq_cpu", src, dst)
+	}
+}
+
+func TestCheckIntoQueryCrossShard(t *testing.T) {
+	old := ShardCount
+	defer func() { ShardCount = old }()
+	ShardCount = 1000
+
+	var src, dst string
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		if ShardKey("cpu") != ShardKey(name) {
+			src, dst = "cpu", name
+			break
+		}
+	}
+	if dst == "" {
+		t.Fatal("could not find a measurement hashing to a different shard than cpu")
+	}
+
+	_, _, _, err := CheckIntoQuery(`select mean(v) into ` + dst + ` from ` + src)
+	if err != ErrIntoCrossShard {
+		t.Errorf("CheckIntoQuery() across shards = %v, want ErrIntoCrossShard", err)
+	}
+}
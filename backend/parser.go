@@ -0,0 +1,73 @@
+// Copyright 2021 Shiwen Cheng. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package backend
+
+// Parser extracts routing information from an InfluxQL query. FastParser is
+// the default; ASTParser trades throughput for correctness on queries the
+// token scanner mis-handles.
+type Parser interface {
+	GetDatabase(q string) (string, error)
+	GetRetentionPolicy(q string) (string, error)
+	GetMeasurement(q string) (string, error)
+	GetIntoTarget(q string) (db, rp, measurement string, err error)
+	CheckQuery(q string, known []string) (tokens []string, check bool, from bool, measurements []string, err error)
+}
+
+// NewParser returns the Parser for mode, defaulting to FastParser.
+func NewParser(mode string) Parser {
+	if mode == "ast" {
+		return ASTParser{}
+	}
+	return FastParser{}
+}
+
+// activeParser is selected via SetParserMode, which the proxy config's
+// `parser: "fast" | "ast"` field is read into at startup.
+var activeParser Parser = FastParser{}
+
+func SetParserMode(mode string) {
+	activeParser = NewParser(mode)
+}
+
+// Check runs activeParser.CheckQuery; callers should use this instead of the
+// package-level CheckQuery so SetParserMode takes effect.
+func Check(q string, known []string) (tokens []string, check bool, from bool, measurements []string, err error) {
+	return activeParser.CheckQuery(q, known)
+}
+
+func GetDatabase(q string) (string, error) {
+	return activeParser.GetDatabase(q)
+}
+
+func GetRetentionPolicy(q string) (string, error) {
+	return activeParser.GetRetentionPolicy(q)
+}
+
+func GetMeasurement(q string) (string, error) {
+	return activeParser.GetMeasurement(q)
+}
+
+// FastParser is the default Parser, implemented on top of ScanTokens.
+type FastParser struct{}
+
+func (FastParser) GetDatabase(q string) (string, error) {
+	return GetDatabaseFromInfluxQL(q)
+}
+
+func (FastParser) GetRetentionPolicy(q string) (string, error) {
+	return GetRetentionPolicyFromInfluxQL(q)
+}
+
+func (FastParser) GetMeasurement(q string) (string, error) {
+	return GetMeasurementFromInfluxQL(q)
+}
+
+func (FastParser) GetIntoTarget(q string) (db, rp, measurement string, err error) {
+	return GetIntoTargetFromTokens(ScanTokens(q, 0))
+}
+
+func (FastParser) CheckQuery(q string, known []string) (tokens []string, check bool, from bool, measurements []string, err error) {
+	return CheckQuery(q, known)
+}
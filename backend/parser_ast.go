@@ -0,0 +1,168 @@
+// Copyright 2021 Shiwen Cheng. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package backend
+
+import (
+	"github.com/influxdata/influxql"
+)
+
+// ASTParser is a Parser backed by github.com/influxdata/influxql's parser.
+type ASTParser struct{}
+
+// measurementSources returns the Sources of any statement the proxy routes
+// on a measurement. DropMeasurementStatement carries its target as a bare
+// Name rather than Sources, so it's wrapped in one here.
+func measurementSources(stmt influxql.Statement) (influxql.Sources, error) {
+	switch s := stmt.(type) {
+	case *influxql.SelectStatement:
+		return s.Sources, nil
+	case *influxql.ShowTagValuesStatement:
+		return s.Sources, nil
+	case *influxql.ShowFieldKeysStatement:
+		return s.Sources, nil
+	case *influxql.ShowSeriesStatement:
+		return s.Sources, nil
+	case *influxql.DeleteSeriesStatement:
+		return s.Sources, nil
+	case *influxql.DropSeriesStatement:
+		return s.Sources, nil
+	case *influxql.DropMeasurementStatement:
+		return influxql.Sources{&influxql.Measurement{Name: s.Name}}, nil
+	default:
+		return nil, ErrIllegalQL
+	}
+}
+
+func (ASTParser) parse(q string) (influxql.Sources, error) {
+	stmt, err := influxql.ParseStatement(q)
+	if err != nil {
+		return nil, err
+	}
+	return measurementSources(stmt)
+}
+
+func (p ASTParser) GetDatabase(q string) (m string, err error) {
+	sources, err := p.parse(q)
+	if err != nil {
+		return "", err
+	}
+	for _, src := range sources {
+		if mm, ok := src.(*influxql.Measurement); ok && mm.Database != "" {
+			return mm.Database, nil
+		}
+	}
+	return "", ErrIllegalQL
+}
+
+func (p ASTParser) GetRetentionPolicy(q string) (m string, err error) {
+	sources, err := p.parse(q)
+	if err != nil {
+		return "", err
+	}
+	for _, src := range sources {
+		if mm, ok := src.(*influxql.Measurement); ok && mm.RetentionPolicy != "" {
+			return mm.RetentionPolicy, nil
+		}
+	}
+	return "", ErrIllegalQL
+}
+
+func (p ASTParser) GetMeasurement(q string) (m string, err error) {
+	sources, err := p.parse(q)
+	if err != nil {
+		return "", err
+	}
+	for _, src := range sources {
+		switch mm := src.(type) {
+		case *influxql.Measurement:
+			if mm.Name != "" {
+				return mm.Name, nil
+			}
+			if mm.Regex != nil {
+				return "/" + mm.Regex.Val.String() + "/", nil
+			}
+		case *influxql.SubQuery:
+			return p.GetMeasurement(mm.Statement.String())
+		}
+	}
+	return "", ErrIllegalQL
+}
+
+func (p ASTParser) GetIntoTarget(q string) (db, rp, measurement string, err error) {
+	stmt, err := influxql.ParseStatement(q)
+	if err != nil {
+		return "", "", "", err
+	}
+	sel, ok := stmt.(*influxql.SelectStatement)
+	if !ok || sel.Target == nil || sel.Target.Measurement == nil {
+		return "", "", "", ErrIllegalQL
+	}
+	mm := sel.Target.Measurement
+	return mm.Database, mm.RetentionPolicy, mm.Name, nil
+}
+
+func (p ASTParser) CheckQuery(q string, known []string) (tokens []string, check bool, from bool, measurements []string, err error) {
+	tokens = ScanTokens(q, 0)
+	stmt, perr := influxql.ParseStatement(q)
+	if perr != nil {
+		return tokens, false, false, nil, nil
+	}
+	switch s := stmt.(type) {
+	case *influxql.SelectStatement:
+		if s.Target != nil {
+			// same shard rule as CheckIntoQuery
+			src := sourceMeasurementName(s.Sources)
+			if src == "" || s.Target.Measurement == nil {
+				return tokens, false, true, nil, ErrIllegalQL
+			}
+			dst := s.Target.Measurement.Name
+			if !SameShard(src, dst) {
+				return tokens, false, true, nil, ErrIntoCrossShard
+			}
+			return tokens, true, true, nil, nil
+		}
+		return tokens, true, true, nil, nil
+	case *influxql.ShowTagValuesStatement, *influxql.ShowFieldKeysStatement, *influxql.ShowSeriesStatement,
+		*influxql.DeleteSeriesStatement, *influxql.DropSeriesStatement, *influxql.DropMeasurementStatement:
+		sources, _ := measurementSources(stmt)
+		measurements, err = expandSourcesIfRegex(sources, known)
+		return tokens, true, true, measurements, err
+	default:
+		t, c, f := p.checkSupportedCmd(tokens)
+		return t, c, f, nil, nil
+	}
+}
+
+func sourceMeasurementName(sources influxql.Sources) string {
+	for _, src := range sources {
+		if mm, ok := src.(*influxql.Measurement); ok {
+			return mm.Name
+		}
+	}
+	return ""
+}
+
+// expandSourcesIfRegex uses the AST's already-compiled regexp rather than
+// re-parsing the /pattern/ token text.
+func expandSourcesIfRegex(sources influxql.Sources, known []string) (measurements []string, err error) {
+	for _, src := range sources {
+		if mm, ok := src.(*influxql.Measurement); ok && mm.Regex != nil {
+			return filterKnownMeasurements(mm.Regex.Val, known), nil
+		}
+	}
+	return nil, nil
+}
+
+func (ASTParser) checkSupportedCmd(tokens []string) (out []string, check bool, from bool) {
+	stmt2 := GetHeadStmtFromTokens(tokens, 2)
+	if SupportCmds[stmt2] {
+		return tokens, true, stmt2 == "delete from" || stmt2 == "drop measurement"
+	}
+	stmt3 := GetHeadStmtFromTokens(tokens, 3)
+	if SupportCmds[stmt3] {
+		return tokens, true, false
+	}
+	return tokens, false, false
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 Shiwen Cheng. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package backend
+
+import "testing"
+
+// benchQueries is a small corpus of representative queries used to compare
+// FastParser against ASTParser: a plain select, a qualified measurement, a
+// regex measurement and a one-level subquery.
+var benchQueries = []string{
+	`select * from cpu where time > now() - 1h`,
+	`select mean(value) from "telegraf"."autogen"."cpu" where host = 'server01'`,
+	`select * from /cpu.*/ limit 10`,
+	`select count(value) from (select value from cpu where time > now() - 1h) group by time(1m)`,
+}
+
+func BenchmarkFastParserGetMeasurement(b *testing.B) {
+	p := NewParser("fast")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, q := range benchQueries {
+			p.GetMeasurement(q)
+		}
+	}
+}
+
+func BenchmarkASTParserGetMeasurement(b *testing.B) {
+	p := NewParser("ast")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, q := range benchQueries {
+			p.GetMeasurement(q)
+		}
+	}
+}
+
+func BenchmarkFastParserCheckQuery(b *testing.B) {
+	p := NewParser("fast")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, q := range benchQueries {
+			p.CheckQuery(q, nil)
+		}
+	}
+}
+
+func BenchmarkASTParserCheckQuery(b *testing.B) {
+	p := NewParser("ast")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, q := range benchQueries {
+			p.CheckQuery(q, nil)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 Shiwen Cheng. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package backend
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var ErrIllegalRegex = errors.New("illegal measurement regex")
+
+func IsRegexMeasurement(m string) bool {
+	return len(m) >= 2 && m[0] == '/'
+}
+
+func ExpandRegexMeasurement(tokens []string, known []string) (measurements []string, err error) {
+	m, err := GetMeasurementFromTokens(tokens)
+	if err != nil {
+		return nil, err
+	}
+	if !IsRegexMeasurement(m) {
+		return nil, ErrIllegalRegex
+	}
+
+	re, err := compileMeasurementRegex(m)
+	if err != nil {
+		return nil, err
+	}
+	return filterKnownMeasurements(re, known), nil
+}
+
+// filterKnownMeasurements returns the names in known matched by re, sorted.
+func filterKnownMeasurements(re *regexp.Regexp, known []string) (measurements []string) {
+	for _, name := range known {
+		if re.MatchString(name) {
+			measurements = append(measurements, name)
+		}
+	}
+	sort.Strings(measurements)
+	return
+}
+
+func compileMeasurementRegex(m string) (*regexp.Regexp, error) {
+	end := strings.LastIndexByte(m, '/')
+	if end <= 0 {
+		return nil, ErrIllegalRegex
+	}
+	pattern := m[1:end]
+	if strings.HasSuffix(m[end:], "i") {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// RewriteRegexMeasurementQuery rebuilds a DELETE FROM /re/ or DROP
+// MEASUREMENT /re/ query with the regex token replaced by measurement, so
+// it can be dispatched to that measurement's owning backend.
+func RewriteRegexMeasurementQuery(tokens []string, measurement string) string {
+	out := make([]string, len(tokens))
+	copy(out, tokens)
+	for i, tok := range out {
+		if IsRegexMeasurement(tok) {
+			out[i] = `"` + measurement + `"`
+		}
+	}
+	return strings.Join(out, " ")
+}
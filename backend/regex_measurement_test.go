@@ -0,0 +1,63 @@
+// Copyright 2021 Shiwen Cheng. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package backend
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandRegexMeasurement(t *testing.T) {
+	known := []string{"cpu", "cpu_load", "disk", "Disk_io"}
+	tests := []struct {
+		q    string
+		want []string
+	}{
+		{"show tag keys from /cpu.*/", []string{"cpu", "cpu_load"}},
+		{"show tag keys from /^disk$/", []string{"disk"}},
+		{"show tag keys from /disk.*/i", []string{"Disk_io", "disk"}},
+		{"show tag keys from cpu", nil},
+	}
+	for _, tt := range tests {
+		got, err := ExpandRegexMeasurement(ScanTokens(tt.q, 0), known)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("ExpandRegexMeasurement(%q): want error for non-regex measurement, got nil", tt.q)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ExpandRegexMeasurement(%q) error = %v", tt.q, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExpandRegexMeasurement(%q) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestCompileMeasurementRegex(t *testing.T) {
+	re, err := compileMeasurementRegex("/cpu.*/")
+	if err != nil || !re.MatchString("cpu_load") {
+		t.Errorf("compileMeasurementRegex(/cpu.*/) failed to match cpu_load: %v", err)
+	}
+
+	re, err = compileMeasurementRegex("/CPU/i")
+	if err != nil || !re.MatchString("cpu") {
+		t.Errorf("compileMeasurementRegex(/CPU/i) failed to case-insensitively match cpu: %v", err)
+	}
+
+	if _, err := compileMeasurementRegex("cpu"); err == nil {
+		t.Error("compileMeasurementRegex(\"cpu\"): want error for non-regex input, got nil")
+	}
+}
+
+func TestRewriteRegexMeasurementQuery(t *testing.T) {
+	got := RewriteRegexMeasurementQuery(ScanTokens("drop measurement /cpu.*/", 0), "cpu_load")
+	want := `drop measurement "cpu_load"`
+	if got != want {
+		t.Errorf("RewriteRegexMeasurementQuery() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2021 Shiwen Cheng. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package backend
+
+import "hash/crc32"
+
+// ShardCount is the number of circles the proxy shards measurements across.
+// It should be set via SetShardCount from the proxy config during startup;
+// it defaults to 1, under which every measurement shares a single shard.
+var ShardCount uint32 = 1
+
+// SetShardCount sets ShardCount to n, ignoring non-positive values.
+func SetShardCount(n int) {
+	if n > 0 {
+		ShardCount = uint32(n)
+	}
+}
+
+// ShardKey returns the shard a measurement hashes to: the CRC-32 (IEEE)
+// checksum of the measurement name modulo ShardCount.
+func ShardKey(measurement string) uint32 {
+	return crc32.ChecksumIEEE([]byte(measurement)) % ShardCount
+}
+
+// SameShard reports whether two measurements hash to the same shard.
+func SameShard(a, b string) bool {
+	return ShardKey(a) == ShardKey(b)
+}
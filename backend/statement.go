@@ -0,0 +1,73 @@
+// Copyright 2021 Shiwen Cheng. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package backend
+
+import "strings"
+
+// SplitStatements splits q on top-level semicolons, ignoring ones inside
+// quotes, brackets or parentheses. Empty statements are dropped.
+func SplitStatements(q string) (stmts []string) {
+	data := []byte(q)
+	depth := 0
+	start := 0
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '"', '\'':
+			end, _, err := FindEndWithQuote(data, i, data[i])
+			if err != nil {
+				i = len(data)
+				break
+			}
+			i = end - 1
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ';':
+			if depth == 0 {
+				if stmt := strings.TrimSpace(string(data[start:i])); stmt != "" {
+					stmts = append(stmts, stmt)
+				}
+				start = i + 1
+			}
+		}
+	}
+	if stmt := strings.TrimSpace(string(data[start:])); stmt != "" {
+		stmts = append(stmts, stmt)
+	}
+	return
+}
+
+// StatementCheck is the result of running Check against one statement of a
+// multi-statement query, tagged with its 0-based position for stitching
+// per-statement results back together in order.
+type StatementCheck struct {
+	StatementID  int
+	Query        string
+	Tokens       []string
+	Check        bool
+	From         bool
+	Measurements []string
+	Err          error
+}
+
+// CheckStatements runs Check against each statement of q, in order.
+func CheckStatements(q string, known []string) []StatementCheck {
+	stmts := SplitStatements(q)
+	results := make([]StatementCheck, len(stmts))
+	for i, stmt := range stmts {
+		tokens, check, from, measurements, err := Check(stmt, known)
+		results[i] = StatementCheck{
+			StatementID:  i,
+			Query:        stmt,
+			Tokens:       tokens,
+			Check:        check,
+			From:         from,
+			Measurements: measurements,
+			Err:          err,
+		}
+	}
+	return results
+}
@@ -0,0 +1,44 @@
+// Copyright 2021 Shiwen Cheng. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package backend
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		q    string
+		want []string
+	}{
+		{"select * from cpu", []string{"select * from cpu"}},
+		{"select * from cpu;select * from disk", []string{"select * from cpu", "select * from disk"}},
+		{"select * from cpu;", []string{"select * from cpu"}},
+		{`select * from cpu where host = 'a;b'`, []string{`select * from cpu where host = 'a;b'`}},
+		{`select * from cpu where time > now() and (a = 1 or b = 2); select * from disk`,
+			[]string{`select * from cpu where time > now() and (a = 1 or b = 2)`, "select * from disk"}},
+		{"  ;  ", nil},
+	}
+	for _, tt := range tests {
+		got := SplitStatements(tt.q)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitStatements(%q) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestCheckStatements(t *testing.T) {
+	results := CheckStatements("select * from cpu;select * from disk", nil)
+	if len(results) != 2 {
+		t.Fatalf("CheckStatements: got %d results, want 2", len(results))
+	}
+	if results[0].StatementID != 0 || results[0].Query != "select * from cpu" || !results[0].Check {
+		t.Errorf("CheckStatements()[0] = %+v, want statement 0 for cpu, checked", results[0])
+	}
+	if results[1].StatementID != 1 || results[1].Query != "select * from disk" || !results[1].Check {
+		t.Errorf("CheckStatements()[1] = %+v, want statement 1 for disk, checked", results[1])
+	}
+}